@@ -0,0 +1,150 @@
+package atcclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/concourse/fly/rc"
+)
+
+// AuthMethod is reported by the ATC's /info endpoint so that fly knows
+// whether to prompt for a username/password to send as Basic Auth, or to
+// run an OAuth2 grant against a UAA-style authorization server.
+type AuthMethod string
+
+const (
+	AuthMethodBasic AuthMethod = "basic"
+	AuthMethodUAA   AuthMethod = "uaa"
+)
+
+// Info is the subset of the ATC's /info response that fly needs in order
+// to authenticate.
+type Info struct {
+	Version    string     `json:"version"`
+	AuthMethod AuthMethod `json:"auth_method"`
+	AuthURL    string     `json:"auth_url"`
+}
+
+// Token is an OAuth2-style bearer token, as issued by the UAA.
+type Token struct {
+	Type         string `json:"token_type"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// GetInfo discovers how the target ATC expects to be authenticated against.
+func (c *client) GetInfo() (Info, error) {
+	var info Info
+
+	err := c.Send(Request{
+		RequestName: "GetInfo",
+		Result:      &info,
+	})
+
+	return info, err
+}
+
+// ErrNoTokenRequired is returned by Login when the target ATC reports
+// Basic Auth (or no auth at all), so there's no token-based grant to run.
+// Callers shouldn't mistake it for a successful login with an empty
+// Token{}.
+var ErrNoTokenRequired = errors.New("target does not use token-based auth")
+
+// Login runs whatever grant is appropriate for the target's auth method and
+// returns the resulting token, ready to be saved onto the target. A blank
+// username and password runs a client_credentials grant against the
+// target's ClientID/ClientSecret, for service-account-style logins;
+// otherwise it runs a password grant.
+func (c *client) Login(username, password string) (Token, error) {
+	info, err := c.GetInfo()
+	if err != nil {
+		return Token{}, err
+	}
+
+	switch info.AuthMethod {
+	case AuthMethodUAA:
+		if info.AuthURL == "" {
+			return Token{}, errors.New("ATC did not report a UAA auth_url")
+		}
+
+		c.target.AuthURL = info.AuthURL
+
+		values := url.Values{"scope": {"openid"}}
+
+		if username == "" && password == "" {
+			values.Set("grant_type", "client_credentials")
+		} else {
+			values.Set("grant_type", "password")
+			values.Set("username", username)
+			values.Set("password", password)
+		}
+
+		return c.uaaGrant(info.AuthURL, values)
+
+	case AuthMethodBasic, "":
+		return Token{}, ErrNoTokenRequired
+
+	default:
+		return Token{}, fmt.Errorf("unsupported auth method: %s", info.AuthMethod)
+	}
+}
+
+func (c *client) refresh() (Token, error) {
+	if c.target.Token == nil || c.target.Token.RefreshToken == "" {
+		return Token{}, errors.New("no refresh token available")
+	}
+
+	if c.target.AuthURL == "" {
+		return Token{}, errors.New("target has no auth_url to refresh against")
+	}
+
+	values := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {c.target.Token.RefreshToken},
+	}
+
+	return c.uaaGrant(c.target.AuthURL, values)
+}
+
+func (c *client) uaaGrant(authURL string, values url.Values) (Token, error) {
+	req, err := http.NewRequest("POST", strings.TrimRight(authURL, "/")+"/oauth/token", strings.NewReader(values.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.target.ClientID, c.target.ClientSecret)
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Token{}, fmt.Errorf("token request failed: %s", response.Status)
+	}
+
+	var token Token
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return Token{}, err
+	}
+
+	c.target.Token = &rc.TargetToken{
+		Type:         token.Type,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+
+	if c.target.Name != "" {
+		if err := rc.SaveTarget(c.target.Name, c.target); err != nil {
+			return Token{}, err
+		}
+	}
+
+	return token, nil
+}
@@ -0,0 +1,286 @@
+package atcclient_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	. "github.com/concourse/fly/atcclient"
+	"github.com/concourse/fly/rc"
+	homedir "github.com/mitchellh/go-homedir"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Auth", func() {
+	var (
+		atcServer *ghttp.Server
+		uaaServer *ghttp.Server
+		target    rc.Target
+		client    Client
+	)
+
+	BeforeEach(func() {
+		atcServer = ghttp.NewServer()
+		uaaServer = ghttp.NewServer()
+
+		target = rc.NewTarget(atcServer.URL(), "", "", "", false)
+		target.ClientID = "fly"
+		target.ClientSecret = "fly-secret"
+
+		var err error
+		client, err = NewClient(target)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		atcServer.Close()
+		uaaServer.Close()
+	})
+
+	Describe("#Login", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/info"),
+					ghttp.RespondWithJSONEncoded(200, Info{
+						AuthMethod: AuthMethodUAA,
+						AuthURL:    uaaServer.URL(),
+					}),
+				),
+			)
+
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.VerifyBasicAuth("fly", "fly-secret"),
+					ghttp.VerifyForm(map[string][]string{
+						"grant_type": {"password"},
+						"username":   {"some-user"},
+						"password":   {"some-password"},
+					}),
+					ghttp.RespondWithJSONEncoded(200, Token{
+						Type:         "bearer",
+						AccessToken:  "access-token",
+						RefreshToken: "refresh-token",
+					}),
+				),
+			)
+		})
+
+		It("discovers the auth method and performs a password grant against the UAA", func() {
+			token, err := client.Login("some-user", "some-password")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.AccessToken).To(Equal("access-token"))
+
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(1))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("#Login with a client_credentials grant", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/info"),
+					ghttp.RespondWithJSONEncoded(200, Info{
+						AuthMethod: AuthMethodUAA,
+						AuthURL:    uaaServer.URL(),
+					}),
+				),
+			)
+
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.VerifyBasicAuth("fly", "fly-secret"),
+					ghttp.VerifyForm(map[string][]string{
+						"grant_type": {"client_credentials"},
+					}),
+					ghttp.RespondWithJSONEncoded(200, Token{
+						Type:        "bearer",
+						AccessToken: "service-account-token",
+					}),
+				),
+			)
+		})
+
+		It("runs a client_credentials grant when no username/password is given", func() {
+			token, err := client.Login("", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.AccessToken).To(Equal("service-account-token"))
+		})
+	})
+
+	Describe("#Login against a Basic Auth target", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/info"),
+					ghttp.RespondWithJSONEncoded(200, Info{AuthMethod: AuthMethodBasic}),
+				),
+			)
+		})
+
+		It("returns ErrNoTokenRequired instead of a silent empty success", func() {
+			token, err := client.Login("some-user", "some-password")
+			Expect(err).To(Equal(ErrNoTokenRequired))
+			Expect(token).To(Equal(Token{}))
+		})
+	})
+
+	Describe("persisting the token", func() {
+		var homeDir string
+
+		BeforeEach(func() {
+			var err error
+			homeDir, err = ioutil.TempDir("", "flyrc")
+			Expect(err).NotTo(HaveOccurred())
+
+			homedir.DisableCache = true
+			os.Setenv("HOME", homeDir)
+
+			target.Name = "default"
+
+			client, err = NewClient(target)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(homeDir)
+		})
+
+		It("saves the token to ~/.flyrc so the next invocation doesn't have to re-prompt", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/info"),
+					ghttp.RespondWithJSONEncoded(200, Info{
+						AuthMethod: AuthMethodUAA,
+						AuthURL:    uaaServer.URL(),
+					}),
+				),
+			)
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.RespondWithJSONEncoded(200, Token{
+						Type:         "bearer",
+						AccessToken:  "access-token",
+						RefreshToken: "refresh-token",
+					}),
+				),
+			)
+
+			token, err := client.Login("some-user", "some-password")
+			Expect(err).NotTo(HaveOccurred())
+
+			saved, err := rc.LoadTarget("default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.Token).To(Equal(&rc.TargetToken{
+				Type:         token.Type,
+				AccessToken:  token.AccessToken,
+				RefreshToken: token.RefreshToken,
+			}))
+		})
+	})
+
+	Describe("#Send with a bearer token", func() {
+		BeforeEach(func() {
+			target.Token = &rc.TargetToken{Type: "bearer", AccessToken: "expired-token", RefreshToken: "refresh-token"}
+			target.AuthURL = uaaServer.URL()
+
+			var err error
+			client, err = NewClient(target)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("attaches the token, refreshes once on a 401, and retries", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines"),
+					ghttp.VerifyHeaderKV("Authorization", "bearer expired-token"),
+					ghttp.RespondWith(http.StatusUnauthorized, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines"),
+					ghttp.VerifyHeaderKV("Authorization", "bearer new-token"),
+					ghttp.RespondWithJSONEncoded(200, []string{}),
+				),
+			)
+
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.VerifyForm(map[string][]string{
+						"grant_type":    {"refresh_token"},
+						"refresh_token": {"refresh-token"},
+					}),
+					ghttp.RespondWithJSONEncoded(200, Token{Type: "bearer", AccessToken: "new-token"}),
+				),
+			)
+
+			var pipelines []string
+			err := client.Send(Request{
+				RequestName: "ListPipelines",
+				Result:      &pipelines,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(2))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("rewinds a seekable streamed body before retrying, instead of uploading an empty one", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.VerifyBody([]byte("jobs: []")),
+					ghttp.RespondWith(http.StatusUnauthorized, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.VerifyBody([]byte("jobs: []")),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+
+			uaaServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.RespondWithJSONEncoded(200, Token{Type: "bearer", AccessToken: "new-token"}),
+				),
+			)
+
+			err := client.Send(Request{
+				RequestName: "SetConfig",
+				Params:      map[string]string{"pipeline_name": "foo"},
+				Body:        strings.NewReader("jobs: []"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("fails instead of resending a drained, non-seekable streamed body", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.RespondWith(http.StatusUnauthorized, ""),
+				),
+			)
+
+			err := client.Send(Request{
+				RequestName: "SetConfig",
+				Params:      map[string]string{"pipeline_name": "foo"},
+				Body:        bufio.NewReader(strings.NewReader("jobs: []")),
+			})
+			Expect(err).To(HaveOccurred())
+
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(1))
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(0))
+		})
+	})
+})
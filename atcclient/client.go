@@ -0,0 +1,419 @@
+package atcclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/rc"
+	"github.com/tedsuo/rata"
+)
+
+//go:generate counterfeiter . Client
+
+// Client talks to a single ATC, translating Requests into HTTP calls
+// against its API and decoding the responses.
+type Client interface {
+	Send(request Request) error
+	SendAll(request Request, result interface{}) error
+	StreamRequest(request Request) (io.ReadCloser, error)
+	Login(username, password string) (Token, error)
+}
+
+type client struct {
+	httpClient        *http.Client
+	requestGenerators []*rata.RequestGenerator
+	apis              []string
+	retryPolicy       RetryPolicy
+	target            rc.Target
+}
+
+// NewClient returns a Client that retries against the target's mirrors,
+// if any are configured, using DefaultRetryPolicy.
+func NewClient(target rc.Target) (Client, error) {
+	return NewClientWithRetryPolicy(target, DefaultRetryPolicy())
+}
+
+// NewClientWithRetryPolicy is like NewClient, but lets the caller tune how
+// aggressively Send fails over across target.MirrorAPIs.
+func NewClientWithRetryPolicy(target rc.Target, retryPolicy RetryPolicy) (Client, error) {
+	if target.API == "" {
+		return nil, errors.New("API is blank")
+	}
+
+	httpClient, err := httpClientFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	apis := append([]string{target.API}, target.MirrorAPIs...)
+
+	requestGenerators := make([]*rata.RequestGenerator, len(apis))
+	for i, api := range apis {
+		requestGenerators[i] = rata.NewRequestGenerator(api, atc.Routes)
+	}
+
+	return &client{
+		httpClient:        httpClient,
+		requestGenerators: requestGenerators,
+		apis:              apis,
+		retryPolicy:       retryPolicy,
+		target:            target,
+	}, nil
+}
+
+func httpClientFor(target rc.Target) (*http.Client, error) {
+	transport := &http.Transport{}
+
+	switch {
+	case target.Insecure:
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	case target.Cert != "":
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(target.Cert)) {
+			return nil, errors.New("failed to parse provided CA certificate")
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func (c *client) Send(passedRequest Request) error {
+	response, apiIndex, err := c.doWithRetry(passedRequest)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized && c.target.Token != nil {
+		if !bodyIsRetryable(passedRequest.Body) {
+			err := c.handleResponse(response, passedRequest)
+			if ure, ok := err.(UnexpectedResponseError); ok {
+				ure.Body = "cannot retry after a token refresh: request body is a non-seekable stream that was already consumed"
+				return ure
+			}
+
+			return err
+		}
+
+		if _, refreshErr := c.refresh(); refreshErr != nil {
+			return c.handleResponse(response, passedRequest)
+		}
+
+		// The first attempt already drained any streamed body; rewind it
+		// before rebuilding the request so the retry uploads the same
+		// bytes instead of nothing.
+		if err := rewindBody(passedRequest.Body); err != nil {
+			return err
+		}
+
+		retryReq, err := c.createHTTPRequest(apiIndex, passedRequest)
+		if err != nil {
+			return err
+		}
+
+		c.setAuth(retryReq)
+
+		response, err = c.httpClient.Do(retryReq)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+	}
+
+	return c.handleResponse(response, passedRequest)
+}
+
+// StreamRequest is like Send, but for endpoints whose response body isn't
+// a single JSON document: build/container event streams and hijacked
+// container I/O. It returns the live response body unbuffered, leaving
+// the caller responsible for closing it.
+func (c *client) StreamRequest(passedRequest Request) (io.ReadCloser, error) {
+	response, _, err := c.doWithRetry(passedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+
+		return nil, UnexpectedResponseError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       string(body),
+		}
+	}
+
+	return response.Body, nil
+}
+
+// doWithRetry attempts passedRequest against each mirrored API in
+// round-robin order, backing off between attempts, until it gets back a
+// response that isn't a retryable failure (or runs out of attempts). It
+// hands back whichever response "won" along with the index of the API it
+// came from, so callers can keep talking to the same mirror (e.g. for a
+// 401 refresh-and-retry).
+func (c *client) doWithRetry(passedRequest Request) (*http.Response, int, error) {
+	attempts := c.retryPolicy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	bodyRetryable := bodyIsRetryable(passedRequest.Body)
+
+	var failures []string
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		apiIndex := attempt % len(c.requestGenerators)
+
+		if attempt > 0 {
+			// A prior attempt against a different mirror has already
+			// drained any io.Reader body; rewind it so this attempt
+			// uploads the same bytes instead of an empty body.
+			if err := rewindBody(passedRequest.Body); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		req, err := c.createHTTPRequest(apiIndex, passedRequest)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		c.setAuth(req)
+
+		response, doErr := c.httpClient.Do(req)
+
+		lastAttempt := attempt == attempts-1
+		canRetry := bodyRetryable && (passedRequest.Idempotent || req.Method != "POST")
+
+		if doErr != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", c.apis[apiIndex], doErr))
+
+			if lastAttempt || !canRetry {
+				break
+			}
+
+			time.Sleep(c.retryPolicy.backoff(attempt))
+			continue
+		}
+
+		if !lastAttempt && canRetry && c.retryPolicy.retryableStatus(response.StatusCode) {
+			body, _ := ioutil.ReadAll(response.Body)
+			response.Body.Close()
+
+			failures = append(failures, fmt.Sprintf("%s: %s (%s)", c.apis[apiIndex], response.Status, string(body)))
+
+			time.Sleep(c.retryPolicy.backoff(attempt))
+			continue
+		}
+
+		return response, apiIndex, nil
+	}
+
+	return nil, 0, fmt.Errorf("request failed against all targets:\n%s", strings.Join(failures, "\n"))
+}
+
+func (c *client) setAuth(req *http.Request) {
+	switch {
+	case c.target.Token != nil:
+		req.Header.Set("Authorization", c.target.Token.Type+" "+c.target.Token.AccessToken)
+
+	case c.target.Username != "":
+		req.SetBasicAuth(c.target.Username, c.target.Password)
+	}
+}
+
+// bodyIsRetryable reports whether a request's Body can safely be sent
+// again on a retry. Struct/nil bodies are re-marshaled from scratch every
+// attempt, so they're always fine; a plain io.Reader is consumed by the
+// first attempt and would resend as empty, so only a seekable one (which
+// rewindBody can rewind) is retryable.
+func bodyIsRetryable(body interface{}) bool {
+	switch body.(type) {
+	case io.ReadSeeker:
+		return true
+	case io.Reader:
+		return false
+	default:
+		return true
+	}
+}
+
+// rewindBody seeks a seekable Body back to the start before a retry. It
+// is a no-op for struct/nil bodies, which createHTTPRequest re-encodes
+// fresh on every attempt.
+func rewindBody(body interface{}) error {
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		return nil
+	}
+
+	_, err := seeker.Seek(0, io.SeekStart)
+	return err
+}
+
+func (c *client) createHTTPRequest(apiIndex int, passedRequest Request) (*http.Request, error) {
+	var body io.Reader
+	isJSON := false
+
+	switch b := passedRequest.Body.(type) {
+	case nil:
+		// no body
+
+	case io.Reader:
+		// streamed as-is, e.g. a pipeline config or task input archive
+		body = b
+
+	default:
+		reqBody, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewBuffer(reqBody)
+		isJSON = true
+	}
+
+	req, err := c.requestGenerators[apiIndex].CreateRequest(
+		string(passedRequest.RequestName),
+		passedRequest.Params,
+		body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if len(passedRequest.Queries) > 0 {
+		values := req.URL.Query()
+		for key, value := range passedRequest.Queries {
+			values.Add(key, value)
+		}
+
+		req.URL.RawQuery = values.Encode()
+	}
+
+	if passedRequest.Page != nil {
+		values := req.URL.Query()
+		for key, value := range passedRequest.Page.QueryParams() {
+			values[key] = value
+		}
+
+		req.URL.RawQuery = values.Encode()
+	}
+
+	return req, nil
+}
+
+func (c *client) handleResponse(response *http.Response, passedRequest Request) error {
+	if passedRequest.Response != nil {
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+
+		*passedRequest.Response = Response{
+			StatusCode: response.StatusCode,
+			Headers:    response.Header,
+			RawBody:    body,
+		}
+	}
+
+	if response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body := []byte(nil)
+		if passedRequest.Response != nil {
+			body = passedRequest.Response.RawBody
+		} else {
+			body, _ = ioutil.ReadAll(response.Body)
+		}
+
+		return UnexpectedResponseError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       string(body),
+			Response:   passedRequest.Response,
+		}
+	}
+
+	if passedRequest.Pagination != nil {
+		pagination, err := parsePagination(response.Header.Get("Link"))
+		if err != nil {
+			return err
+		}
+
+		*passedRequest.Pagination = pagination
+	}
+
+	if passedRequest.Result != nil {
+		if passedRequest.Response != nil {
+			return json.Unmarshal(passedRequest.Response.RawBody, passedRequest.Result)
+		}
+
+		return json.NewDecoder(response.Body).Decode(passedRequest.Result)
+	}
+
+	return nil
+}
+
+// SendAll walks every page of a paginated list endpoint, appending each
+// page's decoded elements onto result (which must be a pointer to a
+// slice), until the Link header stops advertising a next page.
+func (c *client) SendAll(request Request, result interface{}) error {
+	resultValue := reflect.ValueOf(result)
+	if resultValue.Kind() != reflect.Ptr || resultValue.Elem().Kind() != reflect.Slice {
+		return errors.New("SendAll requires a pointer to a slice")
+	}
+
+	sliceValue := resultValue.Elem()
+	elemType := sliceValue.Type().Elem()
+
+	page := request.Page
+
+	for {
+		pageResult := reflect.New(reflect.SliceOf(elemType))
+
+		var pagination Pagination
+
+		pageRequest := request
+		pageRequest.Page = page
+		pageRequest.Result = pageResult.Interface()
+		pageRequest.Pagination = &pagination
+
+		err := c.Send(pageRequest)
+		if err != nil {
+			return err
+		}
+
+		sliceValue.Set(reflect.AppendSlice(sliceValue, pageResult.Elem()))
+
+		if pagination.Next == nil {
+			break
+		}
+
+		page = pagination.Next
+	}
+
+	return nil
+}
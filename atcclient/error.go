@@ -0,0 +1,21 @@
+package atcclient
+
+import "fmt"
+
+// UnexpectedResponseError is returned whenever the ATC responds with a
+// status code outside the 2XX range (204 No Content is treated as success
+// with no body).
+type UnexpectedResponseError struct {
+	StatusCode int
+	Status     string
+	Body       string
+
+	// Response is set on errors from Send whenever the originating Request
+	// asked for one (errors from StreamRequest leave it nil), giving access to the full header set (e.g. for surfacing ATC-side
+	// validation details carried outside the body).
+	Response *Response
+}
+
+func (u UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("unexpected response from ATC:\n%s\n\n%s", u.Status, u.Body)
+}
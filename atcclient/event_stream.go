@@ -0,0 +1,66 @@
+package atcclient
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Event is a single decoded Server-Sent Event frame, as emitted by the
+// ATC's build and container hijack event streams.
+type Event struct {
+	ID   string
+	Name string
+	Data json.RawMessage
+}
+
+// EventStream decodes a live SSE response body one frame at a time, so
+// that callers consuming /api/v1/builds/:id/events or a hijacked
+// container's output never have to buffer the whole stream in memory.
+type EventStream struct {
+	reader *bufio.Reader
+	closer io.Closer
+}
+
+func NewEventStream(body io.ReadCloser) *EventStream {
+	return &EventStream{
+		reader: bufio.NewReader(body),
+		closer: body,
+	}
+}
+
+// NextEvent blocks until a full frame has arrived, returning io.EOF once
+// the server closes the connection.
+func (e *EventStream) NextEvent() (Event, error) {
+	var event Event
+
+	for {
+		line, err := e.reader.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if event.Data != nil {
+				return event, nil
+			}
+
+		case strings.HasPrefix(line, "id:"):
+			event.ID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+
+		case strings.HasPrefix(line, "event:"):
+			event.Name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+
+		case strings.HasPrefix(line, "data:"):
+			event.Data = json.RawMessage(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+}
+
+func (e *EventStream) Close() error {
+	return e.closer.Close()
+}
@@ -0,0 +1,93 @@
+package atcclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "github.com/concourse/fly/atcclient"
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Streaming", func() {
+	var (
+		atcServer *ghttp.Server
+		client    Client
+	)
+
+	BeforeEach(func() {
+		atcServer = ghttp.NewServer()
+
+		var err error
+		client, err = NewClient(rc.NewTarget(atcServer.URL(), "", "", "", false))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		atcServer.Close()
+	})
+
+	Describe("#StreamRequest", func() {
+		It("yields events as they arrive, without waiting for the server to close the connection", func() {
+			proceed := make(chan struct{})
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/foo/events"),
+					func(w http.ResponseWriter, req *http.Request) {
+						flusher := w.(http.Flusher)
+
+						fmt.Fprint(w, "data: {\"n\":1}\n\n")
+						flusher.Flush()
+
+						<-proceed
+
+						fmt.Fprint(w, "data: {\"n\":2}\n\n")
+						flusher.Flush()
+					},
+				),
+			)
+
+			body, err := client.StreamRequest(Request{
+				RequestName: "BuildEvents",
+				Params:      map[string]string{"build_id": "foo"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer body.Close()
+
+			stream := NewEventStream(body)
+
+			first, err := stream.NextEvent()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(first.Data)).To(Equal(`{"n":1}`))
+
+			close(proceed)
+
+			second, err := stream.NextEvent()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(second.Data)).To(Equal(`{"n":2}`))
+		})
+	})
+
+	Describe("streaming a request body", func() {
+		It("sends an io.Reader body through unmodified, for uploads like pipeline configs", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.VerifyBody([]byte("jobs: []")),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+
+			err := client.Send(Request{
+				RequestName: "SetConfig",
+				Params:      map[string]string{"pipeline_name": "foo"},
+				Body:        strings.NewReader("jobs: []"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package atcclient
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// Page selects a window of a paginated list endpoint (builds, resource
+// versions, containers). Since and Until are mutually exclusive cursors
+// into the list; Limit bounds how many results come back.
+type Page struct {
+	Since int
+	Until int
+	Limit int
+}
+
+// Pagination is parsed off of a list response's Link header and points at
+// the adjacent pages, if any exist.
+type Pagination struct {
+	Next     *Page
+	Previous *Page
+}
+
+func (p Page) QueryParams() url.Values {
+	values := url.Values{}
+
+	if p.Since != 0 {
+		values.Add("since", strconv.Itoa(p.Since))
+	}
+
+	if p.Until != 0 {
+		values.Add("until", strconv.Itoa(p.Until))
+	}
+
+	if p.Limit != 0 {
+		values.Add("limit", strconv.Itoa(p.Limit))
+	}
+
+	return values
+}
+
+var linkHeaderSegment = regexp.MustCompile(`<([^>]+)>;\s*rel="([^"]+)"`)
+
+// parsePagination extracts the RFC 5988 Link header relations the ATC
+// emits on list routes into a Pagination.
+func parsePagination(linkHeader string) (Pagination, error) {
+	var pagination Pagination
+
+	for _, match := range linkHeaderSegment.FindAllStringSubmatch(linkHeader, -1) {
+		rawurl, rel := match[1], match[2]
+
+		parsed, err := url.Parse(rawurl)
+		if err != nil {
+			return Pagination{}, err
+		}
+
+		page, err := pageFromQuery(parsed.Query())
+		if err != nil {
+			return Pagination{}, err
+		}
+
+		switch rel {
+		case "next":
+			pagination.Next = page
+		case "previous":
+			pagination.Previous = page
+		}
+	}
+
+	return pagination, nil
+}
+
+func pageFromQuery(query url.Values) (*Page, error) {
+	page := &Page{}
+
+	var err error
+
+	if since := query.Get("since"); since != "" {
+		if page.Since, err = strconv.Atoi(since); err != nil {
+			return nil, err
+		}
+	}
+
+	if until := query.Get("until"); until != "" {
+		if page.Until, err = strconv.Atoi(until); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if page.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,88 @@
+package atcclient_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/concourse/fly/atcclient"
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Pagination", func() {
+	var (
+		atcServer *ghttp.Server
+		client    Client
+	)
+
+	BeforeEach(func() {
+		atcServer = ghttp.NewServer()
+
+		var err error
+		client, err = NewClient(rc.NewTarget(atcServer.URL(), "", "", "", false))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		atcServer.Close()
+	})
+
+	Describe("#Send with a Page", func() {
+		It("renders since/until/limit as query params and parses the Link header", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds", "since=1&limit=2"),
+					ghttp.RespondWith(http.StatusOK, "[]", http.Header{
+						"Content-Type": {"application/json"},
+						"Link": {fmt.Sprintf(
+							`<%s/api/v1/builds?until=2&limit=2>; rel="next", <%s/api/v1/builds?since=4&limit=2>; rel="previous"`,
+							atcServer.URL(), atcServer.URL(),
+						)},
+					}),
+				),
+			)
+
+			var builds []string
+			var pagination Pagination
+			err := client.Send(Request{
+				RequestName: "ListBuilds",
+				Page:        &Page{Since: 1, Limit: 2},
+				Result:      &builds,
+				Pagination:  &pagination,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(pagination.Next).To(Equal(&Page{Until: 2, Limit: 2}))
+			Expect(pagination.Previous).To(Equal(&Page{Since: 4, Limit: 2}))
+		})
+	})
+
+	Describe("#SendAll", func() {
+		It("walks every page until the Link header stops advertising a next page", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWith(http.StatusOK, `["a", "b"]`, http.Header{
+						"Content-Type": {"application/json"},
+						"Link":         {fmt.Sprintf(`<%s/api/v1/builds?until=2>; rel="next"`, atcServer.URL())},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds", "until=2"),
+					ghttp.RespondWith(http.StatusOK, `["c"]`, http.Header{
+						"Content-Type": {"application/json"},
+					}),
+				),
+			)
+
+			var builds []string
+			err := client.SendAll(Request{RequestName: "ListBuilds"}, &builds)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(builds).To(Equal([]string{"a", "b", "c"}))
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,29 @@
+package atcclient
+
+import "github.com/concourse/atc"
+
+// Request describes a single call to the ATC: which route to hit, how to
+// fill it in, what to send, and where to decode the response.
+type Request struct {
+	RequestName atc.RequestName
+	Params      map[string]string
+	Queries     map[string]string
+
+	// Page selects a window of a paginated list endpoint. If set, Send
+	// renders it into query params and, once the response comes back,
+	// populates Pagination (if given) from the response's Link header.
+	Page       *Page
+	Pagination *Pagination
+
+	Body   interface{}
+	Result interface{}
+
+	// Response, if set, is populated by Send with the status code, headers,
+	// and raw body of the ATC's response. StreamRequest does not populate it.
+	Response *Response
+
+	// Idempotent opts a non-idempotent request (e.g. POST) into being
+	// retried against a mirror after a transient failure. GET, PUT, and
+	// DELETE requests are always eligible.
+	Idempotent bool
+}
@@ -0,0 +1,13 @@
+package atcclient
+
+import "net/http"
+
+// Response carries everything about an ATC response beyond the
+// JSON-decoded Result: its status code, its headers (e.g.
+// X-Concourse-Config-Version, Content-Type), and its raw body. Set
+// Request.Response to have Send populate one.
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	RawBody    []byte
+}
@@ -0,0 +1,77 @@
+package atcclient_test
+
+import (
+	"net/http"
+
+	"github.com/concourse/atc"
+	. "github.com/concourse/fly/atcclient"
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Response", func() {
+	var (
+		atcServer *ghttp.Server
+		client    Client
+	)
+
+	BeforeEach(func() {
+		atcServer = ghttp.NewServer()
+
+		var err error
+		client, err = NewClient(rc.NewTarget(atcServer.URL(), "", "", "", false))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		atcServer.Close()
+	})
+
+	It("exposes the status code, headers, and raw body when Response is set", func() {
+		atcServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/pipelines/foo/config"),
+				ghttp.RespondWith(http.StatusOK, "jobs: []", http.Header{
+					atc.ConfigVersionHeader: {"42"},
+					"Content-Type":          {"application/x-yaml"},
+				}),
+			),
+		)
+
+		var response Response
+		err := client.Send(Request{
+			RequestName: atc.GetConfig,
+			Params:      map[string]string{"pipeline_name": "foo"},
+			Response:    &response,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(response.Headers.Get(atc.ConfigVersionHeader)).To(Equal("42"))
+		Expect(response.Headers.Get("Content-Type")).To(Equal("application/x-yaml"))
+		Expect(response.RawBody).To(Equal([]byte("jobs: []")))
+	})
+
+	It("attaches the Response to UnexpectedResponseError", func() {
+		atcServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/api/v1/pipelines/foo"),
+				ghttp.RespondWith(http.StatusInternalServerError, "problem"),
+			),
+		)
+
+		var response Response
+		err := client.Send(Request{
+			RequestName: atc.DeletePipeline,
+			Params:      map[string]string{"pipeline_name": "foo"},
+			Response:    &response,
+		})
+		Expect(err).To(HaveOccurred())
+
+		ure, ok := err.(UnexpectedResponseError)
+		Expect(ok).To(BeTrue())
+		Expect(ure.Response).To(Equal(&response))
+	})
+})
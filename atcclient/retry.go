@@ -0,0 +1,61 @@
+package atcclient
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy governs how Send behaves when it hits a transient failure
+// talking to one of a target's mirrored ATC hosts.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries across all mirrors,
+	// including the first. 0 or 1 means "don't retry".
+	MaxAttempts int
+
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Jitter    time.Duration
+
+	// RetryableStatusCodes are the response status codes that count as
+	// transient failures, worth retrying against the next mirror.
+	RetryableStatusCodes map[int]bool
+}
+
+// DefaultRetryPolicy retries a handful of times against whatever mirrors
+// are configured, backing off exponentially, and treats the status codes
+// a load balancer emits when an upstream ATC is unavailable as
+// retryable.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      50 * time.Millisecond,
+		RetryableStatusCodes: map[int]bool{
+			502: true,
+			503: true,
+			504: true,
+		},
+	}
+}
+
+func (r RetryPolicy) retryableStatus(statusCode int) bool {
+	return r.RetryableStatusCodes[statusCode]
+}
+
+// backoff returns how long to sleep before the (0-indexed) retryAttempt:
+// BaseDelay doubled per attempt and clamped to MaxDelay, plus a random
+// jitter of less than Jitter added after clamping (so the result can
+// exceed MaxDelay by up to Jitter).
+func (r RetryPolicy) backoff(retryAttempt int) time.Duration {
+	delay := r.BaseDelay << uint(retryAttempt)
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	if r.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(r.Jitter)))
+	}
+
+	return delay
+}
@@ -0,0 +1,177 @@
+package atcclient_test
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/concourse/atc"
+	. "github.com/concourse/fly/atcclient"
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Retry and failover", func() {
+	var (
+		primary   *ghttp.Server
+		secondary *ghttp.Server
+		target    rc.Target
+
+		fastRetryPolicy RetryPolicy
+	)
+
+	BeforeEach(func() {
+		primary = ghttp.NewServer()
+		secondary = ghttp.NewServer()
+
+		target = rc.NewTarget(primary.URL(), "", "", "", false)
+		target.MirrorAPIs = []string{secondary.URL()}
+
+		fastRetryPolicy = RetryPolicy{
+			MaxAttempts:          2,
+			BaseDelay:            time.Millisecond,
+			MaxDelay:             time.Millisecond,
+			RetryableStatusCodes: map[int]bool{http.StatusServiceUnavailable: true},
+		}
+	})
+
+	AfterEach(func() {
+		primary.Close()
+		secondary.Close()
+	})
+
+	It("fails over to the next mirror when the first returns a retryable status", func() {
+		primary.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/builds/foo"),
+				ghttp.RespondWith(http.StatusServiceUnavailable, "down for maintenance"),
+			),
+		)
+		secondary.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/builds/foo"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{}),
+			),
+		)
+
+		client, err := NewClientWithRetryPolicy(target, fastRetryPolicy)
+		Expect(err).NotTo(HaveOccurred())
+
+		var build atc.Build
+		err = client.Send(Request{
+			RequestName: atc.GetBuild,
+			Params:      map[string]string{"build_id": "foo"},
+			Result:      &build,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(primary.ReceivedRequests()).To(HaveLen(1))
+		Expect(secondary.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("does not retry a non-idempotent POST by default", func() {
+		primary.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v1/builds"),
+				ghttp.RespondWith(http.StatusServiceUnavailable, "down for maintenance"),
+			),
+		)
+
+		client, err := NewClientWithRetryPolicy(target, fastRetryPolicy)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = client.Send(Request{
+			RequestName: atc.CreateBuild,
+			Body:        atc.Plan{},
+		})
+		Expect(err).To(HaveOccurred())
+
+		Expect(primary.ReceivedRequests()).To(HaveLen(1))
+		Expect(secondary.ReceivedRequests()).To(HaveLen(0))
+	})
+
+	It("retries a POST that opts in via Request.Idempotent", func() {
+		primary.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v1/builds"),
+				ghttp.RespondWith(http.StatusServiceUnavailable, "down for maintenance"),
+			),
+		)
+		secondary.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v1/builds"),
+				ghttp.RespondWith(http.StatusNoContent, ""),
+			),
+		)
+
+		client, err := NewClientWithRetryPolicy(target, fastRetryPolicy)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = client.Send(Request{
+			RequestName: atc.CreateBuild,
+			Body:        atc.Plan{},
+			Idempotent:  true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(primary.ReceivedRequests()).To(HaveLen(1))
+		Expect(secondary.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	Describe("streamed bodies", func() {
+		It("rewinds a seekable body so the mirror gets the full upload, not an empty one", func() {
+			primary.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.VerifyBody([]byte("jobs: []")),
+					ghttp.RespondWith(http.StatusServiceUnavailable, "down for maintenance"),
+				),
+			)
+			secondary.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.VerifyBody([]byte("jobs: []")),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+
+			client, err := NewClientWithRetryPolicy(target, fastRetryPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Send(Request{
+				RequestName: atc.SetConfig,
+				Params:      map[string]string{"pipeline_name": "foo"},
+				Body:        strings.NewReader("jobs: []"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(primary.ReceivedRequests()).To(HaveLen(1))
+			Expect(secondary.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("fails instead of resending a drained, non-seekable body", func() {
+			primary.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/pipelines/foo/config"),
+					ghttp.RespondWith(http.StatusServiceUnavailable, "down for maintenance"),
+				),
+			)
+
+			client, err := NewClientWithRetryPolicy(target, fastRetryPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Send(Request{
+				RequestName: atc.SetConfig,
+				Params:      map[string]string{"pipeline_name": "foo"},
+				Body:        bufio.NewReader(strings.NewReader("jobs: []")),
+			})
+			Expect(err).To(HaveOccurred())
+
+			Expect(primary.ReceivedRequests()).To(HaveLen(1))
+			Expect(secondary.ReceivedRequests()).To(HaveLen(0))
+		})
+	})
+})
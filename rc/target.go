@@ -0,0 +1,49 @@
+package rc
+
+// TargetToken holds the credentials issued by a UAA-style authorization
+// server for a given target. It is cached on disk so that subsequent fly
+// invocations don't have to re-prompt for credentials.
+type TargetToken struct {
+	Type         string `yaml:"type,omitempty"`
+	AccessToken  string `yaml:"value,omitempty"`
+	RefreshToken string `yaml:"refresh_token,omitempty"`
+}
+
+// Target holds everything needed to talk to a particular ATC: where it
+// lives, how to authenticate against it, and (for UAA targets) the tokens
+// that came out of the last login.
+type Target struct {
+	// Name is the key this target was loaded from in ~/.flyrc, if it was
+	// loaded via LoadTarget. A Client uses it to save a refreshed or
+	// newly-issued token back to disk; it's left blank for targets built
+	// directly with NewTarget.
+	Name string
+
+	API      string
+	Username string
+	Password string
+	Cert     string
+	Insecure bool
+
+	// MirrorAPIs are additional ATC URLs, behind the same load balancer
+	// as API, that Send can fail over to on a transient error.
+	MirrorAPIs []string
+
+	// AuthURL is the UAA-style authorization server discovered from the
+	// ATC's /info endpoint. It is only set for targets using token auth.
+	AuthURL      string
+	ClientID     string
+	ClientSecret string
+
+	Token *TargetToken
+}
+
+func NewTarget(api string, username string, password string, cert string, insecure bool) Target {
+	return Target{
+		API:      api,
+		Username: username,
+		Password: password,
+		Cert:     cert,
+		Insecure: insecure,
+	}
+}
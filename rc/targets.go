@@ -0,0 +1,141 @@
+package rc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+// flyrcFileMode restricts the saved targets file to the owner, since it may
+// contain passwords and access/refresh tokens.
+const flyrcFileMode = 0600
+
+type targetDetailsYAML struct {
+	API      string `yaml:"api"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Cert     string `yaml:"cert,omitempty"`
+	Insecure bool   `yaml:"insecure,omitempty"`
+
+	MirrorAPIs []string `yaml:"mirror_apis,omitempty"`
+
+	AuthURL      string       `yaml:"auth_url,omitempty"`
+	ClientID     string       `yaml:"client_id,omitempty"`
+	ClientSecret string       `yaml:"client_secret,omitempty"`
+	Token        *TargetToken `yaml:"token,omitempty"`
+}
+
+type flyrcYAML struct {
+	Targets map[string]targetDetailsYAML `yaml:"targets"`
+}
+
+func flyrcPath() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".flyrc"), nil
+}
+
+func loadFlyrc() (flyrcYAML, error) {
+	var flyrc flyrcYAML
+
+	path, err := flyrcPath()
+	if err != nil {
+		return flyrc, err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return flyrcYAML{Targets: map[string]targetDetailsYAML{}}, nil
+		}
+
+		return flyrc, err
+	}
+
+	err = yaml.Unmarshal(content, &flyrc)
+	if err != nil {
+		return flyrc, err
+	}
+
+	if flyrc.Targets == nil {
+		flyrc.Targets = map[string]targetDetailsYAML{}
+	}
+
+	return flyrc, nil
+}
+
+func saveFlyrc(flyrc flyrcYAML) error {
+	path, err := flyrcPath()
+	if err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(flyrc)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, content, flyrcFileMode)
+	if err != nil {
+		return err
+	}
+
+	// WriteFile only applies the mode when it creates the file, so tighten
+	// the mode of a pre-existing ~/.flyrc as well.
+	return os.Chmod(path, flyrcFileMode)
+}
+
+// SaveTarget persists the given target under name, so that it can later be
+// recalled with LoadTarget without re-entering credentials.
+func SaveTarget(name string, target Target) error {
+	flyrc, err := loadFlyrc()
+	if err != nil {
+		return err
+	}
+
+	flyrc.Targets[name] = targetDetailsYAML{
+		API:      target.API,
+		Username: target.Username,
+		Password: target.Password,
+		Cert:     target.Cert,
+		Insecure: target.Insecure,
+
+		MirrorAPIs: target.MirrorAPIs,
+
+		AuthURL:      target.AuthURL,
+		ClientID:     target.ClientID,
+		ClientSecret: target.ClientSecret,
+		Token:        target.Token,
+	}
+
+	return saveFlyrc(flyrc)
+}
+
+// LoadTarget returns the target previously saved under name.
+func LoadTarget(name string) (Target, error) {
+	flyrc, err := loadFlyrc()
+	if err != nil {
+		return Target{}, err
+	}
+
+	details, found := flyrc.Targets[name]
+	if !found {
+		return Target{}, os.ErrNotExist
+	}
+
+	target := NewTarget(details.API, details.Username, details.Password, details.Cert, details.Insecure)
+	target.Name = name
+	target.MirrorAPIs = details.MirrorAPIs
+	target.AuthURL = details.AuthURL
+	target.ClientID = details.ClientID
+	target.ClientSecret = details.ClientSecret
+	target.Token = details.Token
+
+	return target, nil
+}